@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// datasourceJSONData is the non-secret half of the datasource's kubernetes
+// connection settings, as configured on the datasource config page.
+// kubeconfig/bearerToken/tlsCAData live in DecryptedSecureJSONData instead,
+// since they're credentials.
+type datasourceJSONData struct {
+	APIServerURL      string   `json:"apiServerURL"`
+	ContextName       string   `json:"context"`
+	ImpersonateUser   string   `json:"impersonateUser"`
+	ImpersonateGroups []string `json:"impersonateGroups"`
+}
+
+// buildRESTConfig resolves the *rest.Config a datasource instance should use
+// to reach its target cluster, in order of preference: an embedded
+// kubeconfig, an explicit API server URL + credentials, and finally the
+// in-cluster config for the common case where Grafana itself runs in the
+// cluster being observed.
+func buildRESTConfig(jsonData datasourceJSONData, secureJSONData map[string]string) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+
+	switch {
+	case secureJSONData["kubeconfig"] != "":
+		config, err = restConfigFromKubeconfig([]byte(secureJSONData["kubeconfig"]), jsonData.ContextName)
+	case jsonData.APIServerURL != "":
+		config, err = restConfigFromAPIServer(jsonData, secureJSONData)
+	default:
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applyImpersonation(config, jsonData)
+	return config, nil
+}
+
+// restConfigFromKubeconfig parses an embedded kubeconfig and selects
+// contextName, or the kubeconfig's current-context when contextName is
+// empty.
+func restConfigFromKubeconfig(kubeconfig []byte, contextName string) (*rest.Config, error) {
+	apiConfig, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	clientConfig := clientcmd.NewNonInteractiveClientConfig(*apiConfig, contextName, &clientcmd.ConfigOverrides{}, nil)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build rest config from kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
+// restConfigFromAPIServer builds a *rest.Config that talks directly to an
+// API server URL, for clusters where shipping a full kubeconfig is
+// unnecessary or undesired.
+func restConfigFromAPIServer(jsonData datasourceJSONData, secureJSONData map[string]string) (*rest.Config, error) {
+	config := &rest.Config{Host: jsonData.APIServerURL}
+
+	if ca := secureJSONData["tlsCAData"]; ca != "" {
+		config.TLSClientConfig.CAData = []byte(ca)
+	}
+	if token := secureJSONData["bearerToken"]; token != "" {
+		config.BearerToken = token
+	}
+	return config, nil
+}
+
+func applyImpersonation(config *rest.Config, jsonData datasourceJSONData) {
+	if jsonData.ImpersonateUser == "" && len(jsonData.ImpersonateGroups) == 0 {
+		return
+	}
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: jsonData.ImpersonateUser,
+		Groups:   jsonData.ImpersonateGroups,
+	}
+}
+
+// parseDatasourceJSONData unmarshals a DataSourceInstanceSettings' JSONData
+// blob into datasourceJSONData, tolerating an empty/absent blob.
+func parseDatasourceJSONData(raw json.RawMessage) (datasourceJSONData, error) {
+	var jsonData datasourceJSONData
+	if len(raw) == 0 {
+		return jsonData, nil
+	}
+	if err := json.Unmarshal(raw, &jsonData); err != nil {
+		return datasourceJSONData{}, fmt.Errorf("failed to parse datasource jsonData: %w", err)
+	}
+	return jsonData, nil
+}
+
+// restMapperFor builds a RESTMapper from the cluster's discovery API so GVKs
+// can be resolved to the GroupVersionResource the dynamic client needs.
+func restMapperFor(discoveryClient discovery.DiscoveryInterface) (meta.RESTMapper, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api group resources: %w", err)
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// cachedDiscoveryClient wraps a discovery client with an in-memory cache so
+// that building a RESTMapper and enumerating listable GVKs from the same
+// client, as discovery.go and stream.go both do, fetch the full API resource
+// list from the cluster once rather than twice. Each call returns a fresh
+// cache; nothing is shared between calls.
+func cachedDiscoveryClient(discoveryClient discovery.DiscoveryInterface) discovery.CachedDiscoveryInterface {
+	return memory.NewMemCacheClient(discoveryClient)
+}
+
+// restClientGetter adapts a pre-built *rest.Config to Helm's
+// genericclioptions.RESTClientGetter, the interface action.Configuration.Init
+// expects. Helm itself only ever builds one of these from a kubeconfig file
+// on disk; this datasource may be targeting a cluster described entirely by
+// instance settings, so it supplies its own.
+type restClientGetter struct {
+	config *rest.Config
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return g.config, nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(g.config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(clientcmdapi.Config{}, &clientcmd.ConfigOverrides{})
+}
+
+var _ genericclioptions.RESTClientGetter = (*restClientGetter)(nil)
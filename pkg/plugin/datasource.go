@@ -4,85 +4,87 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	authv1 "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 )
 
-// HelmQuery represents the expected structure of JSON input.
+// HelmQuery represents the expected structure of JSON input. Source selects
+// where ReleaseName is resolved from: "helm" (the default) treats it as a
+// Helm release name directly, "flux" treats it as a Flux HelmRelease whose
+// underlying release is looked up via its status.
 type HelmQuery struct {
 	Namespace   string `json:"namespace"`
 	ReleaseName string `json:"release"`
+	Source      string `json:"source"`
 }
+
+const fluxSource = "flux"
+
 // Make sure Datasource implements required interfaces. This is important to do
 // since otherwise we will only get a not implemented error response from plugin in
 // runtime. In this example datasource instance implements backend.QueryDataHandler.
 var (
-	_ backend.QueryDataHandler      = (*Datasource)(nil)
-type Datasource struct{}
+	_ backend.QueryDataHandler   = (*Datasource)(nil)
+	_ backend.CheckHealthHandler = (*Datasource)(nil)
+)
 
-// Resource represents a Kubernetes resource.
-type Resource struct {
-	Kind   string `json:"kind"`
-	Name   string `json:"name"`
-	Status string `json:"status,omitempty"`
-}
+// Datasource holds the Kubernetes clients resolved for one datasource
+// instance. Each instance can point at a different cluster (in-cluster,
+// kubeconfig, or a bare API server URL), so these are built once in
+// NewDatasource rather than re-derived from rest.InClusterConfig() on every
+// call. uid identifies the instance itself and keys the shared stream
+// watchers, so they don't leak across datasources pointed at different
+// clusters; the negotiated Flux API version is held on the Datasource value.
+type Datasource struct {
+	uid              string
+	restConfig       *rest.Config
+	clientset        kubernetes.Interface
+	dynamicClientset dynamic.Interface
 
-// getKubernetesClient initializes a Kubernetes client.
-func getKubernetesClient() (*kubernetes.Clientset, error) {
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create in-cluster config: %w", err)
-	}
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
-	}
-	return clientset, nil
+	// fluxVersionMu guards the negotiated Flux HelmRelease API version for
+	// this instance's cluster; see negotiatedFluxGVR. fluxVersionResult is
+	// only ever set on a successful negotiation, so an empty string always
+	// means "not yet negotiated, try again".
+	fluxVersionMu     sync.Mutex
+	fluxVersionResult string
 }
 
-// getHelmResources fetches all resources associated with a Helm release.
-func getHelmResources(namespace, releaseName string) ([]Resource, error) {
-	clientset, err := getKubernetesClient()
-	if err != nil {
-		return nil, err
-	}
-
-	labelSelector := fmt.Sprintf("app.kubernetes.io/instance=%s", releaseName)
-	var resources []Resource
-
-	// Fetch Pods
-	pods, err := clientset.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
-	}
-	for _, pod := range pods.Items {
-		resources = append(resources, Resource{"Pod", pod.Name, string(pod.Status.Phase)})
-	}
-
-	// Fetch Services
-	services, err := clientset.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list services: %w", err)
-	}
-	for _, service := range services.Items {
-		resources = append(resources, Resource{"Service", service.Name, ""})
-	}
+// Resource represents a Kubernetes resource owned by a Helm release. GVK is
+// carried alongside the flattened Kind string so callers that need to act on
+// the object (e.g. re-fetching it through the dynamic client) don't have to
+// re-derive group/version from Kind alone.
+type Resource struct {
+	GVK       schema.GroupVersionKind `json:"-"`
+	Kind      string                  `json:"kind"`
+	Namespace string                  `json:"namespace"`
+	Name      string                  `json:"name"`
+	Status    string                  `json:"status,omitempty"`
+	Ready     string                  `json:"ready,omitempty"`
+	Desired   string                  `json:"desired,omitempty"`
+	Reason    string                  `json:"reason,omitempty"`
+	Message   string                  `json:"message,omitempty"`
+	Age       string                  `json:"age,omitempty"`
+}
 
-	// Fetch Deployments
-	deployments, err := clientset.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list deployments: %w", err)
-	}
-	for _, deployment := range deployments.Items {
-		resources = append(resources, Resource{"Deployment", deployment.Name, ""})
-	}
+// kubernetesClient returns the typed client for this datasource instance's
+// cluster.
+func (ds *Datasource) kubernetesClient() kubernetes.Interface {
+	return ds.clientset
+}
 
-	return resources, nil
+// dynamicClient returns the dynamic (GVK-agnostic) client for this
+// datasource instance's cluster.
+func (ds *Datasource) dynamicClient() dynamic.Interface {
+	return ds.dynamicClientset
 }
 
 // QueryData handles requests from Grafana.
@@ -107,27 +109,84 @@ func (ds *Datasource) QueryData(ctx context.Context, req *backend.QueryDataReque
 			response.Responses[query.RefID] = queryData
 			continue
 		}
+
+		// Step 3: No release given means the caller wants the release picker
+		// populated, so list every release deployed in the namespace instead.
 		if helmQuery.ReleaseName == "" {
-			queryData.Error = fmt.Errorf("missing or invalid 'release'")
+			releases, err := ds.listReleases(helmQuery.Namespace)
+			if err != nil {
+				queryData.Error = err
+				response.Responses[query.RefID] = queryData
+				continue
+			}
+
+			frame := data.NewFrame("response",
+				data.NewField("release", nil, []string{}),
+				data.NewField("chart", nil, []string{}),
+				data.NewField("status", nil, []string{}),
+			)
+			for _, rel := range releases {
+				frame.AppendRow(rel.Name, fmt.Sprintf("%s-%s", rel.Chart.Metadata.Name, rel.Chart.Metadata.Version), rel.Info.Status.String())
+			}
+			queryData.Frames = append(queryData.Frames, frame)
 			response.Responses[query.RefID] = queryData
 			continue
 		}
 
-		// Step 3: Fetch resources from Kubernetes
-		resources, err := getHelmResources(helmQuery.Namespace, helmQuery.ReleaseName)
+		// Step 4: When the query targets a Flux HelmRelease instead of a bare
+		// Helm release, resolve it to the underlying release Helm itself
+		// tracks before reusing the same discovery pipeline.
+		namespace, releaseName := helmQuery.Namespace, helmQuery.ReleaseName
+		var fluxStatus *FluxStatus
+		if helmQuery.Source == fluxSource {
+			resolvedRelease, resolvedNamespace, status, err := ds.resolveFluxHelmRelease(ctx, namespace, releaseName)
+			if err != nil {
+				queryData.Error = err
+				response.Responses[query.RefID] = queryData
+				continue
+			}
+			namespace, releaseName = resolvedNamespace, resolvedRelease
+			fluxStatus = &status
+		}
+
+		// Step 5: Fetch the resources the release actually owns
+		resources, err := ds.getHelmResources(ctx, namespace, releaseName)
 		if err != nil {
 			queryData.Error = err
 		} else {
 			queryData.Error = nil
-			// Step 4: Use data.NewFrame for structured response
-			frame := data.NewFrame("response",
+			// Step 6: Use data.NewFrame for structured response
+			fields := []*data.Field{
+				data.NewField("group", nil, []string{}),
+				data.NewField("version", nil, []string{}),
 				data.NewField("kind", nil, []string{}),
+				data.NewField("namespace", nil, []string{}),
 				data.NewField("name", nil, []string{}),
 				data.NewField("status", nil, []string{}),
-			)
+				data.NewField("ready", nil, []string{}),
+				data.NewField("desired", nil, []string{}),
+				data.NewField("reason", nil, []string{}),
+				data.NewField("message", nil, []string{}),
+				data.NewField("age", nil, []string{}),
+			}
+			if fluxStatus != nil {
+				fields = append(fields,
+					data.NewField("fluxReady", nil, []bool{}),
+					data.NewField("fluxReconciling", nil, []bool{}),
+					data.NewField("fluxLastAppliedRevision", nil, []string{}),
+				)
+			}
+			frame := data.NewFrame("response", fields...)
 
 			for _, resource := range resources {
-				frame.AppendRow(resource.Kind, resource.Name, resource.Status)
+				row := []interface{}{
+					resource.GVK.Group, resource.GVK.Version, resource.Kind, resource.Namespace, resource.Name,
+					resource.Status, resource.Ready, resource.Desired, resource.Reason, resource.Message, resource.Age,
+				}
+				if fluxStatus != nil {
+					row = append(row, fluxStatus.Ready, fluxStatus.Reconciling, fluxStatus.LastAppliedRevision)
+				}
+				frame.AppendRow(row...)
 			}
 
 			queryData.Frames = append(queryData.Frames, frame)
@@ -139,17 +198,76 @@ func (ds *Datasource) QueryData(ctx context.Context, req *backend.QueryDataReque
 	return response, nil
 }
 
+// NewDatasource creates a new instance of the datasource, resolving the
+// *rest.Config for its target cluster from the instance's settings: an
+// embedded kubeconfig or an explicit API server URL take precedence, and
+// the process falls back to its in-cluster config otherwise. This lets a
+// single Grafana installation run multiple instances of this datasource,
+// each pointed at a different cluster.
+func NewDatasource(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+	jsonData, err := parseDatasourceJSONData(settings.JSONData)
+	if err != nil {
+		return nil, err
+	}
 
+	restConfig, err := buildRESTConfig(jsonData, settings.DecryptedSecureJSONData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve cluster config: %w", err)
+	}
 
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	dynamicClientset, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
 
+	return &Datasource{
+		uid:              settings.UID,
+		restConfig:       restConfig,
+		clientset:        clientset,
+		dynamicClientset: dynamicClientset,
+	}, nil
+}
 
+// CheckHealth validates that the resolved cluster config can actually reach
+// the API server and that it's allowed to do the things this datasource
+// needs: listing pods and deployments.
+func (ds *Datasource) CheckHealth(ctx context.Context, _ *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	if _, err := ds.clientset.Discovery().ServerVersion(); err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("failed to reach cluster: %v", err),
+		}, nil
+	}
 
+	checks := []authv1.ResourceAttributes{
+		{Verb: "list", Resource: "pods"},
+		{Verb: "list", Resource: "deployments", Group: "apps"},
+	}
+	for _, attrs := range checks {
+		review := &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+		}
+		result, err := ds.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return &backend.CheckHealthResult{
+				Status:  backend.HealthStatusError,
+				Message: fmt.Sprintf("failed to check RBAC for %s %s: %v", attrs.Verb, attrs.Resource, err),
+			}, nil
+		}
+		if !result.Status.Allowed {
+			return &backend.CheckHealthResult{
+				Status:  backend.HealthStatusError,
+				Message: fmt.Sprintf("missing RBAC permission to %s %s", attrs.Verb, attrs.Resource),
+			}, nil
+		}
+	}
 
-
-
-
-
-// NewDatasource creates a new instance of the datasource.
-func NewDatasource(_ backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
-	return &Datasource{}, nil 
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Successfully connected to the cluster",
+	}, nil
 }
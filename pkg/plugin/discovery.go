@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// maxConcurrentGVKListers bounds how many GVKs we list in parallel so a
+// cluster with a large CRD surface doesn't open hundreds of connections at
+// once.
+const maxConcurrentGVKListers = 8
+
+// clusterScopedAllowList are cluster-scoped kinds worth surfacing even
+// though they fall outside a Helm release's own namespace (ClusterRole(Binding)s
+// are the most common thing charts install alongside namespaced resources).
+var clusterScopedAllowList = map[string]bool{
+	"ClusterRole":              true,
+	"ClusterRoleBinding":       true,
+	"CustomResourceDefinition": true,
+	"PersistentVolume":         true,
+	"StorageClass":             true,
+}
+
+// listableGVKs enumerates the GroupVersionKinds exposed by the cluster's
+// discovery API, keeping only kinds that support "list" and are either
+// namespaced or in clusterScopedAllowList.
+func listableGVKs(discoveryClient discovery.DiscoveryInterface) ([]schema.GroupVersionKind, error) {
+	_, apiResourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil && apiResourceLists == nil {
+		return nil, fmt.Errorf("failed to enumerate server api resources: %w", err)
+	}
+
+	var gvks []schema.GroupVersionKind
+	for _, list := range apiResourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, res := range list.APIResources {
+			if !containsVerb(res.Verbs, "list") {
+				continue
+			}
+			if !res.Namespaced && !clusterScopedAllowList[res.Kind] {
+				continue
+			}
+			gvks = append(gvks, gv.WithKind(res.Kind))
+		}
+	}
+	return gvks, nil
+}
+
+func containsVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverResourcesByLabel lists every GVK the cluster exposes, filtered to
+// list-able and (mostly) namespaced kinds, and returns every object carrying
+// the release's app.kubernetes.io/instance label. Unlike the Helm-manifest
+// walk in getHelmResources, this also catches resources a chart's hooks or
+// an operator created after install that never made it into the stored
+// manifest.
+func (ds *Datasource) discoverResourcesByLabel(ctx context.Context, namespace, releaseName string) ([]Resource, error) {
+	discoveryClient := cachedDiscoveryClient(ds.kubernetesClient().Discovery())
+	dynamicClient := ds.dynamicClient()
+
+	mapper, err := restMapperFor(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	gvks, err := listableGVKs(discoveryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	labelSelector := fmt.Sprintf("app.kubernetes.io/instance=%s", releaseName)
+
+	var (
+		mu        sync.Mutex
+		resources []Resource
+	)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentGVKListers)
+
+	for _, gvk := range gvks {
+		gvk := gvk
+		g.Go(func() error {
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				// No mapping (e.g. a kind removed mid-scan) is not fatal to
+				// the rest of the sweep.
+				return nil
+			}
+
+			resourceClient := dynamicClient.Resource(mapping.Resource)
+			var list *unstructured.UnstructuredList
+			if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+				list, err = resourceClient.Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+			} else {
+				list, err = resourceClient.List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+			}
+			if err != nil {
+				// A single unreachable or forbidden GVK (e.g. a CRD without
+				// list RBAC) shouldn't fail discovery for every other kind.
+				return nil
+			}
+
+			mu.Lock()
+			for i := range list.Items {
+				item := list.Items[i]
+				info := extractStatus(gvk.Kind, &item)
+				resources = append(resources, Resource{
+					GVK:       gvk,
+					Kind:      gvk.Kind,
+					Namespace: item.GetNamespace(),
+					Name:      item.GetName(),
+					Status:    info.Status,
+					Ready:     info.Ready,
+					Desired:   info.Desired,
+					Reason:    info.Reason,
+					Message:   info.Message,
+					Age:       info.Age,
+				})
+			}
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
+
+// hydrateManifestResources fetches each resource's live object through the
+// dynamic client and fills in its status fields via extractStatus. It exists
+// for the resources parseManifestResources finds: unlike
+// discoverResourcesByLabel, that walk only has the GVK/namespace/name the
+// manifest declared, not the live object, so without this every resource
+// missing app.kubernetes.io/instance (and therefore invisible to the label
+// sweep) would surface with a permanently blank status. A resource that
+// can't be mapped or fetched (e.g. deleted since install, or no RBAC) is
+// left as-is rather than failing the whole query.
+func (ds *Datasource) hydrateManifestResources(ctx context.Context, resources []Resource) []Resource {
+	discoveryClient := cachedDiscoveryClient(ds.kubernetesClient().Discovery())
+	mapper, err := restMapperFor(discoveryClient)
+	if err != nil {
+		return resources
+	}
+	dynamicClient := ds.dynamicClient()
+
+	hydrated := make([]Resource, len(resources))
+	for i, res := range resources {
+		hydrated[i] = res
+
+		mapping, err := mapper.RESTMapping(res.GVK.GroupKind(), res.GVK.Version)
+		if err != nil {
+			continue
+		}
+
+		resourceClient := dynamicClient.Resource(mapping.Resource)
+		var obj *unstructured.Unstructured
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			obj, err = resourceClient.Namespace(res.Namespace).Get(ctx, res.Name, metav1.GetOptions{})
+		} else {
+			obj, err = resourceClient.Get(ctx, res.Name, metav1.GetOptions{})
+		}
+		if err != nil {
+			continue
+		}
+
+		info := extractStatus(res.Kind, obj)
+		hydrated[i].Status = info.Status
+		hydrated[i].Ready = info.Ready
+		hydrated[i].Desired = info.Desired
+		hydrated[i].Reason = info.Reason
+		hydrated[i].Message = info.Message
+		hydrated[i].Age = info.Age
+	}
+	return hydrated
+}
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fluxHelmReleaseGroup is the API group Flux's helm-controller registers
+// HelmRelease custom resources under.
+const fluxHelmReleaseGroup = "helm.toolkit.fluxcd.io"
+
+// fluxAPIVersionCandidates are probed newest-first; Flux has shipped v2,
+// v2beta2 and v2beta1 across its release history, and a cluster may still
+// be running an older CRD version than the plugin.
+var fluxAPIVersionCandidates = []string{"v2", "v2beta2", "v2beta1"}
+
+// negotiatedFluxGVR probes fluxAPIVersionCandidates once per datasource
+// instance and caches whichever version that instance's cluster actually
+// serves on ds itself, logging the result a single time instead of once per
+// query. The cache lives on ds rather than a package-level var since
+// different instances of this datasource can point at different clusters,
+// each potentially serving a different Flux CRD version.
+//
+// Only a successful negotiation is cached. A transient failure (cluster
+// briefly unreachable, a discovery hiccup) would otherwise be locked in
+// forever by a sync.Once, failing every later query even after the cluster
+// recovers, so a failed attempt is retried on the next call instead.
+func (ds *Datasource) negotiatedFluxGVR(ctx context.Context) (schema.GroupVersionResource, error) {
+	ds.fluxVersionMu.Lock()
+	defer ds.fluxVersionMu.Unlock()
+
+	if ds.fluxVersionResult != "" {
+		return schema.GroupVersionResource{Group: fluxHelmReleaseGroup, Version: ds.fluxVersionResult, Resource: "helmreleases"}, nil
+	}
+
+	dynamicClient := ds.dynamicClient()
+	for _, version := range fluxAPIVersionCandidates {
+		gvr := schema.GroupVersionResource{Group: fluxHelmReleaseGroup, Version: version, Resource: "helmreleases"}
+		if _, err := dynamicClient.Resource(gvr).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{Limit: 1}); err == nil {
+			ds.fluxVersionResult = version
+			log.DefaultLogger.Info("negotiated flux HelmRelease API version", "version", version)
+			return gvr, nil
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("no supported %s API version found among %v", fluxHelmReleaseGroup, fluxAPIVersionCandidates)
+}
+
+// FluxStatus surfaces the Flux-specific reconciliation fields a panel needs
+// to correlate with the pod-level status from getHelmResources.
+type FluxStatus struct {
+	Ready               bool   `json:"ready"`
+	Reconciling         bool   `json:"reconciling"`
+	LastAppliedRevision string `json:"lastAppliedRevision"`
+}
+
+// resolveFluxHelmRelease fetches the Flux HelmRelease named releaseName in
+// namespace and reports the underlying Helm release it manages (name and
+// storage namespace), along with its reconciliation status, so callers can
+// feed the result straight into getHelmResources.
+func (ds *Datasource) resolveFluxHelmRelease(ctx context.Context, namespace, releaseName string) (helmReleaseName, storageNamespace string, status FluxStatus, err error) {
+	gvr, err := ds.negotiatedFluxGVR(ctx)
+	if err != nil {
+		return "", "", status, err
+	}
+
+	obj, err := ds.dynamicClient().Resource(gvr).Namespace(namespace).Get(ctx, releaseName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", status, fmt.Errorf("failed to get flux helmrelease %q: %w", releaseName, err)
+	}
+
+	storageNamespace, _, _ = unstructured.NestedString(obj.Object, "status", "storageNamespace")
+	if storageNamespace == "" {
+		storageNamespace = namespace
+	}
+
+	helmReleaseName = firstHistoryEntryName(obj.Object)
+	if helmReleaseName == "" {
+		// Falls back to the HelmRelease's own name, Flux's default when the
+		// underlying release isn't renamed via spec.releaseName.
+		helmReleaseName = releaseName
+	}
+
+	status.LastAppliedRevision, _, _ = unstructured.NestedString(obj.Object, "status", "lastAppliedRevision")
+	status.Ready, status.Reconciling = fluxConditions(obj.Object)
+
+	return helmReleaseName, storageNamespace, status, nil
+}
+
+// firstHistoryEntryName reads status.history[0].name, the Helm release name
+// Flux most recently applied.
+func firstHistoryEntryName(obj map[string]interface{}) string {
+	history, found, err := unstructured.NestedSlice(obj, "status", "history")
+	if err != nil || !found || len(history) == 0 {
+		return ""
+	}
+	entry, ok := history[0].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := entry["name"].(string)
+	return name
+}
+
+// fluxConditions reads the standard kstatus Ready/Reconciling conditions
+// off a HelmRelease's status.
+func fluxConditions(obj map[string]interface{}) (ready, reconciling bool) {
+	conditions, found, err := unstructured.NestedSlice(obj, "status", "conditions")
+	if err != nil || !found {
+		return false, false
+	}
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _ := cond["type"].(string)
+		condStatus, _ := cond["status"].(string)
+		switch condType {
+		case "Ready":
+			ready = condStatus == "True"
+		case "Reconciling":
+			reconciling = condStatus == "True"
+		}
+	}
+	return ready, reconciling
+}
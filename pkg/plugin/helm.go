@@ -0,0 +1,254 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// newActionConfiguration builds a Helm action.Configuration for namespace,
+// backed by this datasource instance's resolved cluster config and Helm v3's
+// default Secret storage driver.
+func (ds *Datasource) newActionConfiguration(namespace string) (*action.Configuration, error) {
+	actionConfig := new(action.Configuration)
+	getter := &restClientGetter{config: ds.restConfig}
+	if err := actionConfig.Init(getter, namespace, "secret", helmDebugLog); err != nil {
+		return nil, fmt.Errorf("failed to initialize helm action configuration: %w", err)
+	}
+	return actionConfig, nil
+}
+
+// helmDebugLog discards Helm's internal debug logging; callers only care
+// about the error each action returns.
+func helmDebugLog(_ string, _ ...interface{}) {}
+
+// listReleases returns every Helm release deployed in namespace, so callers
+// can populate a release picker without knowing a release name up front.
+func (ds *Datasource) listReleases(namespace string) ([]*release.Release, error) {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	list := action.NewList(actionConfig)
+	list.All = true
+	releases, err := list.Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list helm releases in %q: %w", namespace, err)
+	}
+	return releases, nil
+}
+
+// getReleaseManifest returns the exact manifest Helm rendered and applied
+// for a release, so a panel can show or diff it without re-rendering the
+// chart itself.
+func (ds *Datasource) getReleaseManifest(namespace, releaseName string) (string, error) {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return "", err
+	}
+	rel, err := action.NewGet(actionConfig).Run(releaseName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get helm release %q: %w", releaseName, err)
+	}
+	return rel.Manifest, nil
+}
+
+// getReleaseValues returns the computed values (user-supplied merged over
+// chart defaults) Helm used for a release.
+func (ds *Datasource) getReleaseValues(namespace, releaseName string) (map[string]interface{}, error) {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+	getValues := action.NewGetValues(actionConfig)
+	getValues.AllValues = true
+	values, err := getValues.Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get values for helm release %q: %w", releaseName, err)
+	}
+	return values, nil
+}
+
+// getReleaseHistory returns every recorded revision of a release, newest
+// last, the same order `helm history` prints.
+func (ds *Datasource) getReleaseHistory(namespace, releaseName string) ([]*release.Release, error) {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+	history := action.NewHistory(actionConfig)
+	releases, err := history.Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history for helm release %q: %w", releaseName, err)
+	}
+	releaseutil.SortByRevision(releases)
+	return releases, nil
+}
+
+// getReleaseHooks returns the chart hooks Helm ran for a release's current
+// revision (pre-install, post-upgrade, etc.) along with their last known
+// phase.
+func (ds *Datasource) getReleaseHooks(namespace, releaseName string) ([]*release.Hook, error) {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := action.NewGet(actionConfig).Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get helm release %q: %w", releaseName, err)
+	}
+	return rel.Hooks, nil
+}
+
+// rollbackRelease rolls a release back to revision, or to the previous
+// revision when revision is 0 (Helm's own "no revision given" default).
+func (ds *Datasource) rollbackRelease(namespace, releaseName string, revision int) error {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return err
+	}
+	rollback := action.NewRollback(actionConfig)
+	rollback.Version = revision
+	if err := rollback.Run(releaseName); err != nil {
+		return fmt.Errorf("failed to roll back helm release %q: %w", releaseName, err)
+	}
+	return nil
+}
+
+// uninstallRelease deletes a release and its Helm-tracked resources.
+func (ds *Datasource) uninstallRelease(namespace, releaseName string) error {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return err
+	}
+	uninstall := action.NewUninstall(actionConfig)
+	if _, err := uninstall.Run(releaseName); err != nil {
+		return fmt.Errorf("failed to uninstall helm release %q: %w", releaseName, err)
+	}
+	return nil
+}
+
+// getHelmResources fetches the resources a Helm release owns by combining
+// two complementary views: the objects declared in the release's rendered
+// manifest (exact, but misses anything created after install) and a
+// sweep for everything still carrying the release's
+// app.kubernetes.io/instance label across every listable GVK the cluster
+// exposes, namespaced kinds in namespace only plus the cluster-scoped kinds
+// in clusterScopedAllowList (catches hook-created or operator-created
+// extras, but depends on the label being set). Results are de-duplicated by GVK/namespace/name.
+func (ds *Datasource) getHelmResources(ctx context.Context, namespace, releaseName string) ([]Resource, error) {
+	actionConfig, err := ds.newActionConfiguration(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	get := action.NewGet(actionConfig)
+	rel, err := get.Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get helm release %q: %w", releaseName, err)
+	}
+
+	manifestResources, err := parseManifestResources(namespace, rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	manifestResources = ds.hydrateManifestResources(ctx, manifestResources)
+
+	labeledResources, err := ds.discoverResourcesByLabel(ctx, namespace, releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeResources(manifestResources, labeledResources), nil
+}
+
+// resourceKey identifies a resource independent of which discovery path
+// found it, for de-duplication.
+type resourceKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+// mergeResources unions resource slices, keeping first-seen order. When two
+// entries share a key, the first one is kept unless it has no Status and the
+// later one does.
+func mergeResources(sets ...[]Resource) []Resource {
+	byKey := make(map[resourceKey]Resource)
+	var order []resourceKey
+
+	for _, set := range sets {
+		for _, res := range set {
+			key := resourceKey{gvk: res.GVK, namespace: res.Namespace, name: res.Name}
+			if key.gvk.Empty() {
+				key.gvk = schema.GroupVersionKind{Kind: res.Kind}
+			}
+
+			existing, ok := byKey[key]
+			if !ok {
+				byKey[key] = res
+				order = append(order, key)
+				continue
+			}
+			// Prefer whichever entry actually has live status populated;
+			// the manifest walk only knows what the release declared, the
+			// label sweep knows what the cluster reports.
+			if existing.Status == "" && res.Status != "" {
+				byKey[key] = res
+			}
+		}
+	}
+
+	merged := make([]Resource, 0, len(order))
+	for _, key := range order {
+		merged = append(merged, byKey[key])
+	}
+	return merged
+}
+
+// parseManifestResources decodes a rendered Helm manifest - a YAML stream of
+// zero or more documents - into the kind/namespace/name tuples it defines.
+// Resources that don't set their own namespace inherit defaultNamespace,
+// unless they're a cluster-scoped kind (clusterScopedAllowList) - those stay
+// unnamespaced so they key the same way discoverResourcesByLabel reports
+// them, and mergeResources can actually recognize the two as one object.
+func parseManifestResources(defaultNamespace, manifest string) ([]Resource, error) {
+	var resources []Resource
+
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+	for {
+		var obj unstructured.Unstructured
+		if err := decoder.Decode(&obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode release manifest: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		ns := obj.GetNamespace()
+		if ns == "" && !clusterScopedAllowList[obj.GetKind()] {
+			ns = defaultNamespace
+		}
+
+		resources = append(resources, Resource{
+			GVK:       obj.GroupVersionKind(),
+			Kind:      obj.GetKind(),
+			Namespace: ns,
+			Name:      obj.GetName(),
+		})
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseManifestResources(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-service
+  namespace: other-ns
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: my-clusterrole
+---
+
+`
+	resources, err := parseManifestResources("default-ns", manifest)
+	if err != nil {
+		t.Fatalf("parseManifestResources() error = %v", err)
+	}
+	if len(resources) != 3 {
+		t.Fatalf("len(resources) = %d, want 3", len(resources))
+	}
+
+	if resources[0].Name != "my-configmap" || resources[0].Namespace != "default-ns" {
+		t.Errorf("resources[0] = %+v, want namespace inherited from defaultNamespace", resources[0])
+	}
+	if resources[1].Name != "my-service" || resources[1].Namespace != "other-ns" {
+		t.Errorf("resources[1] = %+v, want its own namespace preserved", resources[1])
+	}
+	// Cluster-scoped kinds must stay unnamespaced, even with no explicit
+	// namespace in the manifest, so they key the same way
+	// discoverResourcesByLabel reports them and mergeResources can dedupe.
+	if resources[2].Name != "my-clusterrole" || resources[2].Namespace != "" {
+		t.Errorf("resources[2] = %+v, want empty namespace for a cluster-scoped kind", resources[2])
+	}
+}
+
+func TestMergeResources_DedupesSameKey(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	manifestOnly := Resource{GVK: gvk, Kind: "Deployment", Namespace: "ns", Name: "app"}
+	labeled := Resource{GVK: gvk, Kind: "Deployment", Namespace: "ns", Name: "app", Status: "Available", Ready: "3"}
+
+	merged := mergeResources([]Resource{manifestOnly}, []Resource{labeled})
+
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1", len(merged))
+	}
+	if merged[0].Status != "Available" {
+		t.Errorf("merged[0].Status = %q, want the labeled entry's populated status to win", merged[0].Status)
+	}
+}
+
+func TestMergeResources_ClusterScopedKindMatchesAcrossPaths(t *testing.T) {
+	// Regression test: a manifest-only entry for a cluster-scoped kind must
+	// key identically to the label-sweep entry for the same object, or the
+	// two show up as separate rows instead of merging.
+	clusterRoleGVK := schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+	fromManifest := Resource{GVK: clusterRoleGVK, Kind: "ClusterRole", Namespace: "", Name: "my-clusterrole"}
+	fromLabelSweep := Resource{GVK: clusterRoleGVK, Kind: "ClusterRole", Name: "my-clusterrole", Status: "N/A"}
+
+	merged := mergeResources([]Resource{fromManifest}, []Resource{fromLabelSweep})
+
+	if len(merged) != 1 {
+		t.Fatalf("len(merged) = %d, want 1 (manifest and label-sweep entries should have merged)", len(merged))
+	}
+}
+
+func TestMergeResources_KeepsDistinctResources(t *testing.T) {
+	a := Resource{Kind: "ConfigMap", Namespace: "ns", Name: "a"}
+	b := Resource{Kind: "ConfigMap", Namespace: "ns", Name: "b"}
+
+	merged := mergeResources([]Resource{a, b})
+	if len(merged) != 2 {
+		t.Fatalf("len(merged) = %d, want 2", len(merged))
+	}
+}
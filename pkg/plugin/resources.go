@@ -0,0 +1,172 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// Make sure Datasource implements backend.CallResourceHandler so Grafana can
+// route /api/datasources/.../resources/* requests to it.
+var _ backend.CallResourceHandler = (*Datasource)(nil)
+
+// rollbackRequest is the expected POST body for the rollback route. A zero
+// Revision rolls back to the previous release, matching `helm rollback`
+// with no revision argument.
+type rollbackRequest struct {
+	Revision int `json:"revision"`
+}
+
+// CallResource implements the release browsing and lifecycle endpoints a
+// Grafana panel can drive without a separate backend:
+//
+//	GET  /releases?namespace={ns}
+//	GET  /releases/{ns}/{name}/manifest
+//	GET  /releases/{ns}/{name}/values
+//	GET  /releases/{ns}/{name}/history
+//	GET  /releases/{ns}/{name}/hooks
+//	POST /releases/{ns}/{name}/rollback
+//	POST /releases/{ns}/{name}/uninstall
+func (ds *Datasource) CallResource(_ context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	path, rawQuery, _ := strings.Cut(req.Path, "?")
+	if rawQuery == "" {
+		if u, err := url.Parse(req.URL); err == nil {
+			rawQuery = u.RawQuery
+		}
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) == 0 || segments[0] != "releases" {
+		return sendJSON(sender, http.StatusNotFound, errorBody(fmt.Sprintf("unknown resource path %q", req.Path)))
+	}
+
+	if len(segments) == 1 {
+		if req.Method != http.MethodGet {
+			return sendJSON(sender, http.StatusMethodNotAllowed, errorBody("only GET is supported on /releases"))
+		}
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return sendJSON(sender, http.StatusBadRequest, errorBody("invalid query string"))
+		}
+		return ds.handleListReleases(sender, query.Get("namespace"))
+	}
+
+	if len(segments) < 3 {
+		return sendJSON(sender, http.StatusNotFound, errorBody(fmt.Sprintf("unknown resource path %q", req.Path)))
+	}
+	namespace, releaseName := segments[1], segments[2]
+	subroute := ""
+	if len(segments) == 4 {
+		subroute = segments[3]
+	}
+
+	switch {
+	case subroute == "manifest" && req.Method == http.MethodGet:
+		return ds.handleGetManifest(sender, namespace, releaseName)
+	case subroute == "values" && req.Method == http.MethodGet:
+		return ds.handleGetValues(sender, namespace, releaseName)
+	case subroute == "history" && req.Method == http.MethodGet:
+		return ds.handleGetHistory(sender, namespace, releaseName)
+	case subroute == "hooks" && req.Method == http.MethodGet:
+		return ds.handleGetHooks(sender, namespace, releaseName)
+	case subroute == "rollback" && req.Method == http.MethodPost:
+		return ds.handleRollback(sender, namespace, releaseName, req.Body)
+	case subroute == "uninstall" && req.Method == http.MethodPost:
+		return ds.handleUninstall(sender, namespace, releaseName)
+	default:
+		return sendJSON(sender, http.StatusNotFound, errorBody(fmt.Sprintf("unknown resource path %q", req.Path)))
+	}
+}
+
+func (ds *Datasource) handleListReleases(sender backend.CallResourceResponseSender, namespace string) error {
+	if namespace == "" {
+		return sendJSON(sender, http.StatusBadRequest, errorBody("missing 'namespace' query parameter"))
+	}
+	releases, err := ds.listReleases(namespace)
+	if err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	return sendJSON(sender, http.StatusOK, releases)
+}
+
+func (ds *Datasource) handleGetManifest(sender backend.CallResourceResponseSender, namespace, releaseName string) error {
+	manifest, err := ds.getReleaseManifest(namespace, releaseName)
+	if err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	return sendJSON(sender, http.StatusOK, map[string]string{"manifest": manifest})
+}
+
+func (ds *Datasource) handleGetValues(sender backend.CallResourceResponseSender, namespace, releaseName string) error {
+	values, err := ds.getReleaseValues(namespace, releaseName)
+	if err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	return sendJSON(sender, http.StatusOK, values)
+}
+
+func (ds *Datasource) handleGetHistory(sender backend.CallResourceResponseSender, namespace, releaseName string) error {
+	history, err := ds.getReleaseHistory(namespace, releaseName)
+	if err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	return sendJSON(sender, http.StatusOK, history)
+}
+
+func (ds *Datasource) handleGetHooks(sender backend.CallResourceResponseSender, namespace, releaseName string) error {
+	hooks, err := ds.getReleaseHooks(namespace, releaseName)
+	if err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	return sendJSON(sender, http.StatusOK, hooks)
+}
+
+func (ds *Datasource) handleRollback(sender backend.CallResourceResponseSender, namespace, releaseName string, body []byte) error {
+	var reqBody rollbackRequest
+	if len(body) > 0 {
+		if err := json.NewDecoder(strings.NewReader(string(body))).Decode(&reqBody); err != nil && err != io.EOF {
+			return sendJSON(sender, http.StatusBadRequest, errorBody("invalid rollback request body"))
+		}
+	}
+
+	if err := ds.rollbackRelease(namespace, releaseName, reqBody.Revision); err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	status := "rolled back to previous revision"
+	if reqBody.Revision != 0 {
+		status = "rolled back to revision " + strconv.Itoa(reqBody.Revision)
+	}
+	return sendJSON(sender, http.StatusOK, map[string]string{"status": status})
+}
+
+func (ds *Datasource) handleUninstall(sender backend.CallResourceResponseSender, namespace, releaseName string) error {
+	if err := ds.uninstallRelease(namespace, releaseName); err != nil {
+		return sendJSON(sender, http.StatusInternalServerError, errorBody(err.Error()))
+	}
+	return sendJSON(sender, http.StatusOK, map[string]string{"status": "uninstalled"})
+}
+
+func errorBody(message string) map[string]string {
+	return map[string]string{"error": message}
+}
+
+// sendJSON marshals body as JSON and sends it through sender with status.
+func sendJSON(sender backend.CallResourceResponseSender, status int, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status: status,
+		Headers: map[string][]string{
+			"Content-Type": {"application/json"},
+		},
+		Body: payload,
+	})
+}
@@ -0,0 +1,260 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// statusInfo is the normalized status a Grafana panel can color or
+// threshold on, independent of which Kubernetes kind produced it.
+type statusInfo struct {
+	Status  string
+	Ready   string
+	Desired string
+	Reason  string
+	Message string
+	Age     string
+}
+
+// kindsWithNoStatus are RBAC/policy objects that don't carry a meaningful
+// runtime status; reporting "N/A" keeps panels from rendering a blank cell.
+var kindsWithNoStatus = map[string]bool{
+	"Role":               true,
+	"RoleBinding":        true,
+	"ClusterRole":        true,
+	"ClusterRoleBinding": true,
+	"PodSecurityPolicy":  true,
+}
+
+// extractStatus derives statusInfo for obj according to its kind, falling
+// back to the generic .status.phase field most other Kubernetes objects
+// populate when there's no bespoke extractor for the kind.
+func extractStatus(kind string, obj *unstructured.Unstructured) statusInfo {
+	info := statusInfo{Age: ageOf(obj)}
+
+	if kindsWithNoStatus[kind] {
+		info.Status = "N/A"
+		return info
+	}
+
+	switch kind {
+	case "Deployment":
+		return extractDeploymentStatus(obj, info)
+	case "StatefulSet":
+		return extractStatefulSetStatus(obj, info)
+	case "DaemonSet":
+		return extractDaemonSetStatus(obj, info)
+	case "Pod":
+		return extractPodStatus(obj, info)
+	case "Job":
+		return extractJobStatus(obj, info)
+	case "PersistentVolumeClaim":
+		return extractPVCStatus(obj, info)
+	case "Service":
+		return extractServiceStatus(obj, info)
+	case "Ingress":
+		return extractIngressStatus(obj, info)
+	default:
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		info.Status = phase
+		return info
+	}
+}
+
+func extractDeploymentStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var deployment appsv1.Deployment
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &deployment); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+
+	info.Ready = fmt.Sprintf("%d", deployment.Status.ReadyReplicas)
+	info.Desired = fmt.Sprintf("%d", deployment.Status.Replicas)
+	info.Status = "Progressing"
+	for _, cond := range deployment.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionTrue {
+			info.Status = "Available"
+		}
+		if cond.Type == appsv1.DeploymentProgressing {
+			info.Reason = cond.Reason
+			info.Message = cond.Message
+		}
+	}
+	return info
+}
+
+func extractStatefulSetStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var sts appsv1.StatefulSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &sts); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+
+	info.Ready = fmt.Sprintf("%d", sts.Status.ReadyReplicas)
+	info.Desired = fmt.Sprintf("%d", sts.Status.Replicas)
+	if sts.Status.ReadyReplicas == sts.Status.Replicas {
+		info.Status = "Ready"
+	} else {
+		info.Status = "Progressing"
+	}
+	return info
+}
+
+func extractDaemonSetStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var ds appsv1.DaemonSet
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ds); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+
+	info.Ready = fmt.Sprintf("%d", ds.Status.NumberReady)
+	info.Desired = fmt.Sprintf("%d", ds.Status.DesiredNumberScheduled)
+	if ds.Status.NumberReady == ds.Status.DesiredNumberScheduled {
+		info.Status = "Ready"
+	} else {
+		info.Status = "Progressing"
+	}
+	return info
+}
+
+func extractPodStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var pod corev1.Pod
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pod); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+
+	info.Status = string(pod.Status.Phase)
+
+	ready := 0
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Ready {
+			ready++
+		}
+		if cs.State.Waiting != nil && info.Reason == "" {
+			info.Reason = cs.State.Waiting.Reason
+			info.Message = cs.State.Waiting.Message
+		}
+	}
+	info.Ready = fmt.Sprintf("%d", ready)
+	info.Desired = fmt.Sprintf("%d", len(pod.Status.ContainerStatuses))
+	if info.Reason != "" {
+		info.Status = info.Reason
+	}
+	return info
+}
+
+func extractJobStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var job batchv1.Job
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &job); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+
+	info.Ready = fmt.Sprintf("%d", job.Status.Succeeded)
+	if job.Spec.Completions != nil {
+		info.Desired = fmt.Sprintf("%d", *job.Spec.Completions)
+	}
+	info.Message = fmt.Sprintf("active=%d failed=%d", job.Status.Active, job.Status.Failed)
+
+	switch {
+	case job.Status.Failed > 0:
+		info.Status = "Failed"
+	case job.Spec.Completions != nil && job.Status.Succeeded >= *job.Spec.Completions:
+		info.Status = "Complete"
+	default:
+		info.Status = "Running"
+	}
+	return info
+}
+
+func extractPVCStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var pvc corev1.PersistentVolumeClaim
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &pvc); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+	info.Status = string(pvc.Status.Phase)
+	return info
+}
+
+func extractServiceStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var svc corev1.Service
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &svc); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+	info.Status = string(svc.Spec.Type)
+
+	ips := svc.Spec.ExternalIPs
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			ips = append(ips, ingress.IP)
+		}
+	}
+	info.Message = fmt.Sprintf("clusterIP=%s externalIPs=%v", svc.Spec.ClusterIP, ips)
+	return info
+}
+
+func extractIngressStatus(obj *unstructured.Unstructured, info statusInfo) statusInfo {
+	var ingress networkingv1.Ingress
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, &ingress); err != nil {
+		info.Status = "Unknown"
+		return info
+	}
+
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	var address string
+	if len(ingress.Status.LoadBalancer.Ingress) > 0 {
+		lb := ingress.Status.LoadBalancer.Ingress[0]
+		if lb.IP != "" {
+			address = lb.IP
+		} else {
+			address = lb.Hostname
+		}
+	}
+
+	info.Status = "Ready"
+	if address == "" {
+		info.Status = "Pending"
+	}
+	info.Message = fmt.Sprintf("hosts=%v address=%s", hosts, address)
+	return info
+}
+
+// ageOf formats how long ago obj was created the way kubectl does: a single
+// coarse unit (days, then hours, then minutes).
+func ageOf(obj *unstructured.Unstructured) string {
+	created := obj.GetCreationTimestamp()
+	if created.IsZero() {
+		return ""
+	}
+
+	d := timeSince(created.Time)
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// timeSince is a thin wrapper around time.Since and the only place in this
+// file that reads wall-clock time.
+func timeSince(t time.Time) time.Duration {
+	return time.Since(t)
+}
@@ -0,0 +1,221 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// toUnstructured converts a typed object into the shape extractStatus's
+// per-kind extractors expect, mirroring how the dynamic client hands objects
+// back in production.
+func toUnstructured(t *testing.T, kind string, obj interface{}) *unstructured.Unstructured {
+	t.Helper()
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatalf("failed to convert %s fixture to unstructured: %v", kind, err)
+	}
+	u := &unstructured.Unstructured{Object: m}
+	u.SetKind(kind)
+	return u
+}
+
+func TestExtractStatus_Deployment(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas: 2,
+			Replicas:      3,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Reason: "NewReplicaSetAvailable", Message: "rollout in progress"},
+			},
+		},
+	}
+	info := extractStatus("Deployment", toUnstructured(t, "Deployment", deployment))
+
+	if info.Status != "Progressing" {
+		t.Errorf("Status = %q, want %q", info.Status, "Progressing")
+	}
+	if info.Ready != "2" || info.Desired != "3" {
+		t.Errorf("Ready/Desired = %q/%q, want 2/3", info.Ready, info.Desired)
+	}
+	if info.Reason != "NewReplicaSetAvailable" {
+		t.Errorf("Reason = %q, want %q", info.Reason, "NewReplicaSetAvailable")
+	}
+}
+
+func TestExtractStatus_DeploymentAvailable(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas: 3,
+			Replicas:      3,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			},
+		},
+	}
+	info := extractStatus("Deployment", toUnstructured(t, "Deployment", deployment))
+	if info.Status != "Available" {
+		t.Errorf("Status = %q, want %q", info.Status, "Available")
+	}
+}
+
+func TestExtractStatus_StatefulSet(t *testing.T) {
+	cases := []struct {
+		name   string
+		status appsv1.StatefulSetStatus
+		want   string
+	}{
+		{"ready", appsv1.StatefulSetStatus{ReadyReplicas: 3, Replicas: 3}, "Ready"},
+		{"progressing", appsv1.StatefulSetStatus{ReadyReplicas: 1, Replicas: 3}, "Progressing"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sts := &appsv1.StatefulSet{Status: tc.status}
+			info := extractStatus("StatefulSet", toUnstructured(t, "StatefulSet", sts))
+			if info.Status != tc.want {
+				t.Errorf("Status = %q, want %q", info.Status, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractStatus_DaemonSet(t *testing.T) {
+	ds := &appsv1.DaemonSet{
+		Status: appsv1.DaemonSetStatus{NumberReady: 2, DesiredNumberScheduled: 3},
+	}
+	info := extractStatus("DaemonSet", toUnstructured(t, "DaemonSet", ds))
+	if info.Status != "Progressing" {
+		t.Errorf("Status = %q, want %q", info.Status, "Progressing")
+	}
+	if info.Ready != "2" || info.Desired != "3" {
+		t.Errorf("Ready/Desired = %q/%q, want 2/3", info.Ready, info.Desired)
+	}
+}
+
+func TestExtractStatus_Pod(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Ready: false, State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "rate limited"}}},
+				{Ready: true},
+			},
+		},
+	}
+	info := extractStatus("Pod", toUnstructured(t, "Pod", pod))
+	if info.Status != "ImagePullBackOff" {
+		t.Errorf("Status = %q, want %q", info.Status, "ImagePullBackOff")
+	}
+	if info.Ready != "1" || info.Desired != "2" {
+		t.Errorf("Ready/Desired = %q/%q, want 1/2", info.Ready, info.Desired)
+	}
+}
+
+func TestExtractStatus_Job(t *testing.T) {
+	completions := int32(3)
+	job := &batchv1.Job{
+		Spec:   batchv1.JobSpec{Completions: &completions},
+		Status: batchv1.JobStatus{Succeeded: 3, Active: 0, Failed: 0},
+	}
+	info := extractStatus("Job", toUnstructured(t, "Job", job))
+	if info.Status != "Complete" {
+		t.Errorf("Status = %q, want %q", info.Status, "Complete")
+	}
+	if info.Ready != "3" || info.Desired != "3" {
+		t.Errorf("Ready/Desired = %q/%q, want 3/3", info.Ready, info.Desired)
+	}
+}
+
+func TestExtractStatus_PVC(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}}
+	info := extractStatus("PersistentVolumeClaim", toUnstructured(t, "PersistentVolumeClaim", pvc))
+	if info.Status != "Bound" {
+		t.Errorf("Status = %q, want %q", info.Status, "Bound")
+	}
+}
+
+func TestExtractStatus_Service(t *testing.T) {
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, ClusterIP: "10.0.0.1"}}
+	info := extractStatus("Service", toUnstructured(t, "Service", svc))
+	if info.Status != "ClusterIP" {
+		t.Errorf("Status = %q, want %q", info.Status, "ClusterIP")
+	}
+}
+
+func TestExtractStatus_Ingress(t *testing.T) {
+	cases := []struct {
+		name    string
+		ingress *networkingv1.Ingress
+		want    string
+	}{
+		{
+			name: "pending",
+			ingress: &networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "example.com"}}},
+			},
+			want: "Pending",
+		},
+		{
+			name: "ready",
+			ingress: &networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{Rules: []networkingv1.IngressRule{{Host: "example.com"}}},
+				Status: networkingv1.IngressStatus{
+					LoadBalancer: networkingv1.IngressLoadBalancerStatus{
+						Ingress: []networkingv1.IngressLoadBalancerIngress{{IP: "1.2.3.4"}},
+					},
+				},
+			},
+			want: "Ready",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			info := extractStatus("Ingress", toUnstructured(t, "Ingress", tc.ingress))
+			if info.Status != tc.want {
+				t.Errorf("Status = %q, want %q", info.Status, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractStatus_NoStatusKind(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetKind("ClusterRole")
+	info := extractStatus("ClusterRole", u)
+	if info.Status != "N/A" {
+		t.Errorf("Status = %q, want %q", info.Status, "N/A")
+	}
+}
+
+func TestExtractStatus_GenericPhaseFallback(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"phase": "Active"},
+	}}
+	u.SetKind("Namespace")
+	info := extractStatus("Namespace", u)
+	if info.Status != "Active" {
+		t.Errorf("Status = %q, want %q", info.Status, "Active")
+	}
+}
+
+func TestAgeOf(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetCreationTimestamp(metav1.NewTime(metav1.Now().Add(-50 * time.Hour)))
+	if got, want := ageOf(u), "2d"; got != want {
+		t.Errorf("ageOf() = %q, want %q", got, want)
+	}
+}
+
+func TestAgeOf_NoCreationTimestamp(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if got := ageOf(u); got != "" {
+		t.Errorf("ageOf() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,285 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Make sure Datasource implements backend.StreamHandler so a panel can
+// subscribe to ds/<uid>/release/<ns>/<name> for live resource updates
+// instead of polling QueryData.
+var _ backend.StreamHandler = (*Datasource)(nil)
+
+// releaseInformerResyncPeriod controls how often the shared informers do a
+// full relist on top of their watch, as a safety net against missed events.
+const releaseInformerResyncPeriod = 5 * time.Minute
+
+// parseStreamPath extracts the namespace and release name from a
+// "release/{namespace}/{name}" channel path.
+func parseStreamPath(path string) (namespace, releaseName string, err error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != 3 || segments[0] != "release" {
+		return "", "", fmt.Errorf("unsupported stream path %q, expected release/{namespace}/{name}", path)
+	}
+	return segments[1], segments[2], nil
+}
+
+// SubscribeStream validates that path names a release this datasource can
+// watch before Grafana starts routing frames for it.
+func (ds *Datasource) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if _, _, err := parseStreamPath(req.Path); err != nil {
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusOK}, nil
+}
+
+// RunStream keeps sending resource-change frames for the release named in
+// req.Path until ctx is cancelled, sharing one set of informers across
+// however many subscribers are currently watching that release.
+func (ds *Datasource) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	namespace, releaseName, err := parseStreamPath(req.Path)
+	if err != nil {
+		return err
+	}
+
+	watcher, ch, release := acquireReleaseWatcher(ds.uid, namespace, releaseName)
+	defer release()
+
+	if err := watcher.ensureStarted(ds, namespace, releaseName); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-ch:
+			frame := data.NewFrame("release/"+releaseName,
+				data.NewField("event", nil, []string{event.eventType}),
+				data.NewField("kind", nil, []string{event.resource.Kind}),
+				data.NewField("namespace", nil, []string{event.resource.Namespace}),
+				data.NewField("name", nil, []string{event.resource.Name}),
+				data.NewField("status", nil, []string{event.resource.Status}),
+			)
+			if err := sender.SendFrame(frame, data.IncludeAll); err != nil {
+				return fmt.Errorf("failed to send stream frame: %w", err)
+			}
+		}
+	}
+}
+
+// PublishStream is not supported: resource state flows from the cluster to
+// Grafana, never the other way.
+func (ds *Datasource) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{Status: backend.PublishStreamStatusPermissionDenied}, nil
+}
+
+// watchEvent is one Add/Update/Delete translated from an informer callback
+// into the shape RunStream sends over the wire.
+type watchEvent struct {
+	eventType string
+	resource  Resource
+}
+
+// releaseWatcherKey identifies a shared set of informers. datasourceUID is
+// part of the key because each datasource instance can point at a different
+// cluster; without it, two instances watching a same-named release in a
+// same-named namespace on different clusters would share one watcher and
+// leak or drop events depending on which instance happened to start it.
+type releaseWatcherKey struct {
+	datasourceUID string
+	namespace     string
+	release       string
+}
+
+// releaseWatcher owns the informer factories (one namespaced, one
+// cluster-scoped) for one (datasource, namespace, release) key and fans their
+// events out to every current subscriber. Subscribers are
+// added/removed via acquireReleaseWatcher/release so the factory can be torn
+// down once nobody is watching.
+type releaseWatcher struct {
+	mu          sync.Mutex
+	refCount    int
+	started     bool
+	cancel      context.CancelFunc
+	subscribers map[chan watchEvent]struct{}
+}
+
+var (
+	watcherRegistryMu sync.Mutex
+	watcherRegistry   = map[releaseWatcherKey]*releaseWatcher{}
+)
+
+// acquireReleaseWatcher returns the shared watcher for (namespace, release)
+// and a channel subscribed to its events, creating the watcher on first use.
+// The caller must defer the returned release func exactly once: it
+// unsubscribes ch before dropping the watcher's refcount, so a disconnecting
+// subscriber's channel is always removed from w.subscribers rather than
+// leaking for the shared watcher's lifetime.
+func acquireReleaseWatcher(datasourceUID, namespace, releaseName string) (*releaseWatcher, chan watchEvent, func()) {
+	key := releaseWatcherKey{datasourceUID: datasourceUID, namespace: namespace, release: releaseName}
+
+	watcherRegistryMu.Lock()
+	w, ok := watcherRegistry[key]
+	if !ok {
+		w = &releaseWatcher{subscribers: make(map[chan watchEvent]struct{})}
+		watcherRegistry[key] = w
+	}
+	watcherRegistryMu.Unlock()
+
+	w.mu.Lock()
+	w.refCount++
+	w.mu.Unlock()
+
+	ch := w.subscribe()
+
+	release := func() {
+		w.unsubscribe(ch)
+
+		w.mu.Lock()
+		w.refCount--
+		shouldStop := w.refCount <= 0
+		cancel := w.cancel
+		w.mu.Unlock()
+
+		if !shouldStop {
+			return
+		}
+		watcherRegistryMu.Lock()
+		delete(watcherRegistry, key)
+		watcherRegistryMu.Unlock()
+		if cancel != nil {
+			cancel()
+		}
+	}
+
+	return w, ch, release
+}
+
+// subscribe registers a channel for this RunStream call and returns it; the
+// channel is unbuffered-safe (sized) so a slow subscriber can't block
+// informer callbacks for the others.
+func (w *releaseWatcher) subscribe() chan watchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ch := make(chan watchEvent, 32)
+	w.subscribers[ch] = struct{}{}
+	return ch
+}
+
+// unsubscribe removes ch from w.subscribers so broadcast stops writing to it
+// once its RunStream call has returned.
+func (w *releaseWatcher) unsubscribe(ch chan watchEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.subscribers, ch)
+}
+
+func (w *releaseWatcher) broadcast(event watchEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Drop rather than block the informer's event loop; the next
+			// resync will catch the subscriber back up.
+		}
+	}
+}
+
+// ensureStarted lazily builds the dynamic informer factories for this
+// release's GVKs and starts watching, scoped to the release's label
+// selector. Safe to call repeatedly; only the first caller pays the
+// discovery cost.
+//
+// Namespaced and cluster-scoped GVKs need separate factories: a factory
+// built with a namespace issues Namespace(namespace).List/Watch for every
+// resource it's given, and cluster-scoped kinds (the ones in
+// clusterScopedAllowList, e.g. ClusterRole(Binding)) don't support that -
+// their reflector would fail and retry forever. discoverResourcesByLabel
+// branches the same way for the same reason.
+func (w *releaseWatcher) ensureStarted(ds *Datasource, namespace, releaseName string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return nil
+	}
+
+	dynamicClient := ds.dynamicClient()
+	discoveryClient := cachedDiscoveryClient(ds.kubernetesClient().Discovery())
+	mapper, err := restMapperFor(discoveryClient)
+	if err != nil {
+		return err
+	}
+	gvks, err := listableGVKs(discoveryClient)
+	if err != nil {
+		return err
+	}
+
+	labelSelector := fmt.Sprintf("app.kubernetes.io/instance=%s", releaseName)
+	tweakListOptions := func(opts *metav1.ListOptions) {
+		opts.LabelSelector = labelSelector
+	}
+	namespacedFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, releaseInformerResyncPeriod, namespace, tweakListOptions)
+	clusterScopedFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, releaseInformerResyncPeriod, metav1.NamespaceAll, tweakListOptions)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+
+	for _, gvk := range gvks {
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			continue
+		}
+
+		factory := namespacedFactory
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			factory = clusterScopedFactory
+		}
+
+		informer := factory.ForResource(mapping.Resource).Informer()
+		informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { w.handleEvent("Added", obj) },
+			UpdateFunc: func(_, obj interface{}) { w.handleEvent("Modified", obj) },
+			DeleteFunc: func(obj interface{}) { w.handleEvent("Deleted", obj) },
+		})
+	}
+
+	namespacedFactory.Start(ctx.Done())
+	clusterScopedFactory.Start(ctx.Done())
+	w.started = true
+	return nil
+}
+
+func (w *releaseWatcher) handleEvent(eventType string, obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	kind := u.GetKind()
+	info := extractStatus(kind, u)
+	w.broadcast(watchEvent{
+		eventType: eventType,
+		resource: Resource{
+			Kind:      kind,
+			Namespace: u.GetNamespace(),
+			Name:      u.GetName(),
+			Status:    info.Status,
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package plugin
+
+import "testing"
+
+func TestParseStreamPath(t *testing.T) {
+	namespace, releaseName, err := parseStreamPath("release/my-ns/my-release")
+	if err != nil {
+		t.Fatalf("parseStreamPath() error = %v", err)
+	}
+	if namespace != "my-ns" || releaseName != "my-release" {
+		t.Errorf("parseStreamPath() = (%q, %q), want (\"my-ns\", \"my-release\")", namespace, releaseName)
+	}
+}
+
+func TestParseStreamPath_Invalid(t *testing.T) {
+	for _, path := range []string{"", "release", "release/my-ns", "other/my-ns/my-release"} {
+		if _, _, err := parseStreamPath(path); err == nil {
+			t.Errorf("parseStreamPath(%q) error = nil, want error", path)
+		}
+	}
+}